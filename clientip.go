@@ -0,0 +1,139 @@
+package jote
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// A set of IPs and/or CIDR ranges considered to be trusted reverse proxies.
+// Only requests whose immediate peer address is in this set are allowed to set client-IP headers
+// (Forwarded, X-Forwarded-For, X-Real-IP); everyone else's headers are ignored.
+type TrustedProxies struct {
+	ips  []net.IP
+	nets []*net.IPNet
+}
+
+// Builds a [TrustedProxies] from a list of IPs (e.g. "10.0.0.1") and/or CIDRs (e.g. "10.0.0.0/8").
+// Returns an error if any entry parses as neither.
+func NewTrustedProxies(entries ...string) (*TrustedProxies, error) {
+	tp := &TrustedProxies{}
+	for _, entry := range entries {
+		if ip := net.ParseIP(entry); ip != nil {
+			tp.ips = append(tp.ips, ip)
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		tp.nets = append(tp.nets, ipnet)
+	}
+	return tp, nil
+}
+
+// Returns true if ip is one of the trusted IPs or falls inside one of the trusted CIDR ranges.
+func (tp *TrustedProxies) Contains(ip net.IP) bool {
+	if tp == nil {
+		return false
+	}
+	for _, trusted := range tp.ips {
+		if trusted.Equal(ip) {
+			return true
+		}
+	}
+	for _, n := range tp.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Extracts the real client IP from a request, trusting client-IP headers only when the request's
+// immediate peer (r.RemoteAddr) is in Trusted. Behind multiple reverse proxies, XFF and the RFC 7239
+// Forwarded header are walked right-to-left, skipping hops that are themselves trusted proxies, and
+// the first untrusted address is returned.
+type ClientIPExtractor struct {
+	// Header names to consult, in order. Defaults to "Forwarded", "X-Forwarded-For", "X-Real-IP".
+	Headers []string
+	// The set of reverse proxies allowed to set the headers above.
+	Trusted *TrustedProxies
+}
+
+// Creates a [ClientIPExtractor] with the default header order ("Forwarded", "X-Forwarded-For", "X-Real-IP").
+func NewClientIPExtractor(trusted *TrustedProxies) *ClientIPExtractor {
+	return &ClientIPExtractor{
+		Headers: []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"},
+		Trusted: trusted,
+	}
+}
+
+// Returns the client IP for r, falling back to the host portion of r.RemoteAddr if no header yields
+// one (either because the peer isn't trusted or because none of the headers are present/valid).
+func (e *ClientIPExtractor) Extract(r *http.Request) string {
+	remoteHost := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteHost); err == nil {
+		remoteHost = host
+	}
+	remoteIP := net.ParseIP(remoteHost)
+	if remoteIP == nil || !e.Trusted.Contains(remoteIP) {
+		return remoteHost
+	}
+	for _, header := range e.Headers {
+		var hops []string
+		if strings.EqualFold(header, "Forwarded") {
+			hops = parseForwardedFor(r.Header.Get(header))
+		} else if v := r.Header.Get(header); v != "" {
+			for _, hop := range strings.Split(v, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+		if ip := e.firstUntrustedRightToLeft(hops); ip != "" {
+			return ip
+		}
+	}
+	return remoteHost
+}
+
+// Walks hops right-to-left (the rightmost entries are the closest, most-trusted hops) and returns
+// the first address that is not itself a trusted proxy.
+func (e *ClientIPExtractor) firstUntrustedRightToLeft(hops []string) string {
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			continue
+		}
+		if e.Trusted.Contains(ip) {
+			continue
+		}
+		return ip.String()
+	}
+	return ""
+}
+
+// Extracts the "for=" parameters of an RFC 7239 Forwarded header, in the order they appear, stripping
+// quotes, brackets and ports. Returns nil if header is empty or has no "for=" parameters.
+func parseForwardedFor(header string) []string {
+	if header == "" {
+		return nil
+	}
+	var fors []string
+	for _, hop := range strings.Split(header, ",") {
+		for _, param := range strings.Split(hop, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(k), "for") {
+				continue
+			}
+			v = strings.Trim(strings.TrimSpace(v), `"`)
+			v = strings.TrimPrefix(v, "[")
+			if idx := strings.LastIndex(v, "]"); idx >= 0 {
+				v = v[:idx]
+			} else if host, _, err := net.SplitHostPort(v); err == nil {
+				v = host
+			}
+			fors = append(fors, v)
+		}
+	}
+	return fors
+}
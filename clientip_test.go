@@ -0,0 +1,95 @@
+package jote
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxiesContains(t *testing.T) {
+	tp, err := NewTrustedProxies("10.0.0.1", "192.168.0.0/16")
+	if err != nil {
+		t.Fatalf("NewTrustedProxies: %v", err)
+	}
+
+	cases := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.0.1", true},
+		{"192.168.1.5", true},
+		{"8.8.8.8", false},
+	}
+	for _, c := range cases {
+		if got := tp.Contains(mustParseIP(t, c.ip)); got != c.want {
+			t.Errorf("Contains(%s) = %v, want %v", c.ip, got, c.want)
+		}
+	}
+}
+
+func TestTrustedProxiesNewInvalidEntry(t *testing.T) {
+	if _, err := NewTrustedProxies("not-an-ip-or-cidr"); err == nil {
+		t.Fatal("expected an error for an invalid entry")
+	}
+}
+
+func TestClientIPExtractor_UntrustedPeerIgnoresHeaders(t *testing.T) {
+	tp, _ := NewTrustedProxies("10.0.0.1")
+	e := NewClientIPExtractor(tp)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.9:1234"
+	r.Header.Set("X-Real-IP", "1.2.3.4")
+
+	if got := e.Extract(r); got != "203.0.113.9" {
+		t.Errorf("Extract() = %q, want %q (header should be ignored from an untrusted peer)", got, "203.0.113.9")
+	}
+}
+
+func TestClientIPExtractor_TrustedPeerWalksXFFRightToLeft(t *testing.T) {
+	tp, _ := NewTrustedProxies("10.0.0.1", "10.0.0.2")
+	e := NewClientIPExtractor(tp)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	// Rightmost hop (10.0.0.2) is itself a trusted proxy, so it should be skipped.
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.2")
+
+	if got := e.Extract(r); got != "203.0.113.9" {
+		t.Errorf("Extract() = %q, want %q", got, "203.0.113.9")
+	}
+}
+
+func TestClientIPExtractor_ForwardedHeader(t *testing.T) {
+	tp, _ := NewTrustedProxies("10.0.0.1")
+	e := NewClientIPExtractor(tp)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r.Header.Set("Forwarded", `for=192.0.2.60;proto=http;by=203.0.113.43`)
+
+	if got := e.Extract(r); got != "192.0.2.60" {
+		t.Errorf("Extract() = %q, want %q", got, "192.0.2.60")
+	}
+}
+
+func TestClientIPExtractor_NoTrustedProxiesConfigured(t *testing.T) {
+	e := NewClientIPExtractor(nil)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.9:1234"
+	r.Header.Set("X-Real-IP", "1.2.3.4")
+
+	if got := e.Extract(r); got != "203.0.113.9" {
+		t.Errorf("Extract() = %q, want %q (no trusted proxies means no header is honored)", got, "203.0.113.9")
+	}
+}
+
+func mustParseIP(t *testing.T, s string) net.IP {
+	t.Helper()
+	ip := net.ParseIP(s)
+	if ip == nil {
+		t.Fatalf("net.ParseIP(%q) failed", s)
+	}
+	return ip
+}
@@ -0,0 +1,228 @@
+package jote
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Configures [NewLogger]. The zero value logs unrotated text at the default level to Path.
+type LoggerConfig struct {
+	// Destination file, or "stdout" to log to stdout (in which case rotation settings are ignored).
+	Path string
+	// "text" or "json". Defaults to "text".
+	Format string
+	// Starting level, controllable afterwards via the returned *slog.LevelVar.
+	Level slog.Level
+	// Rotate once the file would exceed this size. <= 0 disables rotation.
+	MaxSizeMB int
+	// Keep at most this many rotated backups, oldest deleted first. <= 0 keeps all of them.
+	MaxBackups int
+	// Delete rotated backups older than this many days. <= 0 keeps them regardless of age.
+	MaxAgeDays int
+	// gzip rotated backups.
+	Compress bool
+}
+
+// Builds a [log/slog.Logger] from cfg, returning the [slog.LevelVar] to adjust its level at runtime
+// and an [io/Closer] to close the underlying file (a no-op for "stdout"). Panics if the log file
+// can't be opened, the same way [CreateLogger] does.
+func NewLogger(cfg LoggerConfig) (*slog.Logger, *slog.LevelVar, io.Closer) {
+	loglvl := new(slog.LevelVar)
+	loglvl.Set(cfg.Level)
+	opts := &slog.HandlerOptions{Level: loglvl}
+
+	if cfg.Path == "stdout" {
+		return slog.New(newLogHandler(cfg.Format, os.Stdout, opts)), loglvl, nopCloser{}
+	}
+
+	w, err := newRotatingWriter(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return slog.New(newLogHandler(cfg.Format, w, opts)), loglvl, w
+}
+
+func newLogHandler(format string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Writes to a log file, rotating it to path.YYYYMMDD-HHMMSS once it would exceed MaxSizeMB, then
+// pruning old backups by MaxBackups/MaxAgeDays and optionally gzipping them. Safe for concurrent use.
+type rotatingWriter struct {
+	mu sync.Mutex
+
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	maxAge       time.Duration
+	compress     bool
+
+	file *os.File
+	size int64
+}
+
+func newRotatingWriter(cfg LoggerConfig) (*rotatingWriter, error) {
+	w := &rotatingWriter{
+		path:         cfg.Path,
+		maxSizeBytes: int64(cfg.MaxSizeMB) << 20,
+		maxBackups:   cfg.MaxBackups,
+		maxAge:       time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		compress:     cfg.Compress,
+	}
+	if err := w.openCurrent(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotatingWriter) openCurrent() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSizeBytes > 0 && w.size+int64(len(p)) > w.maxSizeBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	backupPath := w.nextBackupPath()
+	if err := os.Rename(w.path, backupPath); err != nil {
+		return err
+	}
+	if w.compress {
+		if err := gzipFile(backupPath); err == nil {
+			os.Remove(backupPath)
+		}
+	}
+
+	if err := w.openCurrent(); err != nil {
+		return err
+	}
+	w.size = 0
+	w.prune()
+	return nil
+}
+
+// Builds a backup path for the current rotation, disambiguating with a "-NN" suffix if two
+// rotations land in the same second (second-resolution timestamps alone would collide and
+// os.Rename would silently clobber the earlier backup).
+// The suffix is one past the highest one already used for this second (plain or .gz), so a name
+// freed by pruning is not reused and newer backups keep sorting after older ones.
+func (w *rotatingWriter) nextBackupPath() string {
+	base := w.path + "." + time.Now().Format("20060102-150405")
+	matches, _ := filepath.Glob(base + "*")
+	if len(matches) == 0 {
+		return base
+	}
+	next := 1
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, base), ".gz")
+		if n, err := strconv.Atoi(strings.TrimPrefix(suffix, "-")); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("%s-%02d", base, next)
+}
+
+// Deletes rotated backups beyond MaxBackups and/or older than MaxAgeDays.
+func (w *rotatingWriter) prune() {
+	if w.maxBackups <= 0 && w.maxAge <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return
+	}
+	// the "YYYYMMDD-HHMMSS[-NN]" suffix sorts chronologically once a trailing ".gz" is ignored
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.TrimSuffix(matches[i], ".gz") < strings.TrimSuffix(matches[j], ".gz")
+	})
+
+	cutoff := time.Now().Add(-w.maxAge)
+	for i, m := range matches {
+		remove := w.maxAge > 0 && isOlderThan(m, cutoff)
+		if w.maxBackups > 0 && len(matches)-i > w.maxBackups {
+			remove = true
+		}
+		if remove {
+			os.Remove(m)
+		}
+	}
+}
+
+func isOlderThan(path string, cutoff time.Time) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().Before(cutoff)
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		return err
+	}
+	return gw.Close()
+}
+
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
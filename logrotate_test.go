@@ -0,0 +1,113 @@
+package jote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingWriterRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := newRotatingWriter(LoggerConfig{Path: path, MaxSizeMB: 0})
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+	// MaxSizeMB: 0 disables rotation; force a tiny threshold directly since MaxSizeMB only takes whole MB.
+	w.maxSizeBytes = 10
+	defer w.Close()
+
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("more-bytes-that-should-trigger-rotation")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected exactly 1 backup after exceeding the size threshold, got %d: %v", len(backups), backups)
+	}
+}
+
+func TestRotatingWriterDisambiguatesSameSecondCollisions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := newRotatingWriter(LoggerConfig{Path: path})
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+	defer w.Close()
+
+	// Two rotations landing in the same wall-clock second must not collide: the first backup must
+	// still exist after the second rotation, instead of being silently clobbered by os.Rename.
+	if err := w.rotate(); err != nil {
+		t.Fatalf("first rotate: %v", err)
+	}
+	if err := w.rotate(); err != nil {
+		t.Fatalf("second rotate: %v", err)
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 distinct backups, got %d: %v", len(backups), backups)
+	}
+}
+
+func TestRotatingWriterPrunesByMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := newRotatingWriter(LoggerConfig{Path: path, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := w.rotate(); err != nil {
+			t.Fatalf("rotate %d: %v", i, err)
+		}
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected pruning to keep only 2 backups, got %d: %v", len(backups), backups)
+	}
+}
+
+func TestNewLoggerStdoutCloserIsNoop(t *testing.T) {
+	_, _, closer := NewLogger(LoggerConfig{Path: "stdout"})
+	if err := closer.Close(); err != nil {
+		t.Fatalf("stdout closer should be a no-op, got: %v", err)
+	}
+}
+
+func TestNewLoggerWritesToFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	logger, _, closer := NewLogger(LoggerConfig{Path: path, Format: "json"})
+	defer closer.Close()
+
+	logger.Info("hello")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected the log file to contain the logged line")
+	}
+}
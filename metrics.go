@@ -0,0 +1,147 @@
+package jote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default latency histogram buckets, in seconds.
+var defaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metricsSeriesKey struct {
+	method      string
+	pattern     string
+	statusClass string
+}
+
+// Per-series counters. The bucket counts are cumulative (le semantics): bucket[i] counts every
+// observation <= buckets[i], same as the Prometheus histogram exposition format expects.
+type metricsSeries struct {
+	count   atomic.Uint64
+	sumNs   atomic.Uint64
+	buckets []atomic.Uint64
+}
+
+// A pluggable metrics backend maintaining per-(method, route pattern, status class) request
+// counters and a latency histogram, exposed in the Prometheus text exposition format via [Metrics.Handler].
+// Counter/histogram updates are atomic; the mutex is read-locked to look up a series (and while
+// [Metrics.Handler] renders) and write-locked only to create a new series, which happens once per
+// distinct (method, pattern, status class) combination.
+type Metrics struct {
+	buckets []float64
+
+	mu     sync.RWMutex
+	series map[metricsSeriesKey]*metricsSeries
+}
+
+// Creates a [Metrics] with the given latency buckets (in seconds). If buckets is empty, it defaults
+// to 5ms, 10ms, 25ms, 50ms, 100ms, 250ms, 500ms, 1s, 2.5s, 5s, 10s.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = defaultMetricsBuckets
+	}
+	return &Metrics{
+		buckets: buckets,
+		series:  make(map[metricsSeriesKey]*metricsSeries),
+	}
+}
+
+// Returns a [Middleware] that records one observation per request: a count and a latency sample
+// keyed by method, route pattern and status class ("2xx", "4xx", ...). The pattern comes from
+// [net/http.Request.Pattern], which [net/http.ServeMux] populates before invoking the registered
+// handler chain, so this needs no extra routing lookup and no reference to the dispatch mux.
+// Falls back to the raw path if Pattern is empty (e.g. the handler wasn't reached through a mux).
+func (m *Metrics) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lrw := &loggingResponseWriter{ResponseWriter: w, rc: 200}
+			next.ServeHTTP(lrw, r)
+
+			pattern := r.Pattern
+			if pattern == "" {
+				pattern = r.URL.Path
+			}
+			m.observe(r.Method, pattern, lrw.rc, time.Since(start))
+		})
+	}
+}
+
+func (m *Metrics) observe(method string, pattern string, status int, dur time.Duration) {
+	s := m.seriesFor(method, pattern, metricsStatusClass(status))
+	s.count.Add(1)
+	s.sumNs.Add(uint64(dur.Nanoseconds()))
+	secs := dur.Seconds()
+	for i, le := range m.buckets {
+		if secs <= le {
+			s.buckets[i].Add(1)
+		}
+	}
+}
+
+func (m *Metrics) seriesFor(method string, pattern string, statusClass string) *metricsSeries {
+	key := metricsSeriesKey{method: method, pattern: pattern, statusClass: statusClass}
+
+	m.mu.RLock()
+	s, ok := m.series[key]
+	m.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.series[key]; ok {
+		return s
+	}
+	s = &metricsSeries{buckets: make([]atomic.Uint64, len(m.buckets))}
+	m.series[key] = s
+	return s
+}
+
+func metricsStatusClass(status int) string {
+	switch {
+	case status >= 500:
+		return "5xx"
+	case status >= 400:
+		return "4xx"
+	case status >= 300:
+		return "3xx"
+	case status >= 200:
+		return "2xx"
+	default:
+		return "1xx"
+	}
+}
+
+// Renders all recorded series in the Prometheus text exposition format: a http_requests_total
+// counter and a http_request_duration_seconds histogram, both labeled by method, pattern and status.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		io.WriteString(w, "# HELP http_requests_total Total number of HTTP requests.\n")
+		io.WriteString(w, "# TYPE http_requests_total counter\n")
+		for key, s := range m.series {
+			fmt.Fprintf(w, "http_requests_total{method=%q,pattern=%q,status=%q} %d\n", key.method, key.pattern, key.statusClass, s.count.Load())
+		}
+
+		io.WriteString(w, "# HELP http_request_duration_seconds Request latency in seconds.\n")
+		io.WriteString(w, "# TYPE http_request_duration_seconds histogram\n")
+		for key, s := range m.series {
+			labels := fmt.Sprintf("method=%q,pattern=%q,status=%q", key.method, key.pattern, key.statusClass)
+			for i, le := range m.buckets {
+				fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=%q} %d\n", labels, strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i].Load())
+			}
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count.Load())
+			fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(time.Duration(s.sumNs.Load()).Seconds(), 'g', -1, 64))
+			fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, s.count.Load())
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package jote
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,8 +10,28 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 )
 
+// Tuning knobs for a [Mid] client, passed to [NewMidadolWithOptions].
+// The zero value means: no caching, a 5 second timeout, no retries.
+type Options struct {
+	// How long a successfully fetched value is served from the in-memory cache before it is
+	// re-fetched. <= 0 disables caching.
+	TTL time.Duration
+	// Timeout for the underlying *http.Client. <= 0 defaults to 5 seconds.
+	Timeout time.Duration
+	// Number of retries (in addition to the first attempt) for transient errors: network errors
+	// and 5xx responses. 404 never retries.
+	Retries int
+	// Initial backoff between retries, doubled after each attempt. <= 0 defaults to 100ms.
+	Backoff time.Duration
+	// Transport for the underlying *http.Client. nil uses [net/http.DefaultTransport].
+	// Set this to use a proxy, custom TLS config, or a mock transport in tests.
+	Transport http.RoundTripper
+}
+
 // Object to query the Midadol config server
 type Mid struct {
 	// Target URL, where the Midadol server is running, e.g. http://localhost:5911
@@ -18,51 +40,72 @@ type Mid struct {
 	App string
 	// Environment name, this is optional as "" is a valid environment
 	Env string
+
+	client *http.Client
+	opts   Options
+	cache  *midCache
+}
+
+type midCache struct {
+	mu      sync.Mutex
+	entries map[string]midCacheEntry
+}
+
+type midCacheEntry struct {
+	value     string
+	expiresAt time.Time
 }
 
 // Shortcut to create a Mid object where the env is "" and the app is the executable name taken from os.Args[0].
 // If the URL is "" it will use "http://localhost:5911" as the URL
 func NewMidadolSimple(url string) Mid {
-	if url == "" {
-		url = "http://localhost:5911"
-	}
-	return Mid{
-		URL: url,
-		App: filepath.Base(os.Args[0]),
-	}
+	return NewMidadolWithOptions(url, filepath.Base(os.Args[0]), "", Options{})
 }
 
 // Creates a Mid object for querying the Midadol config server
 func NewMidadol(url string, app string, env string) Mid {
+	return NewMidadolWithOptions(url, app, env, Options{})
+}
+
+// Same as [NewMidadol] but lets the caller configure caching, timeouts and retries via [Options].
+func NewMidadolWithOptions(midURL string, app string, env string, opts Options) Mid {
+	if midURL == "" {
+		midURL = "http://localhost:5911"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 100 * time.Millisecond
+	}
 	return Mid{
-		URL: url,
-		App: app,
-		Env: env,
+		URL:    midURL,
+		App:    app,
+		Env:    env,
+		client: &http.Client{Timeout: opts.Timeout, Transport: opts.Transport},
+		opts:   opts,
+		cache:  &midCache{entries: make(map[string]midCacheEntry)},
 	}
 }
 
-// Gets the config value as a string. It panics if the response code of the Midadol server is 404.
-// If the request of http.Get creates a non-nil error it also panics.
-// Nothing gets cached, so every Get* sends an http request to the Midadol server.
-// The choice to panic was deliberate, because config values should be loaded on startup.
+// Gets the config value as a string. It panics if the response code of the Midadol server is 404,
+// if all retries are exhausted, or if the request could not be built.
+// Served from the in-memory cache if [Options.TTL] is set and the cached entry hasn't expired.
 func (mid *Mid) Get(key string) string {
-	url, err := url.JoinPath(mid.URL, "get", mid.App, key)
-	if err != nil {
-		panic(fmt.Errorf("[MIDADOL] ERROR: could not join URL: %s", err))
-	}
-	resp, err := http.Get(url)
+	val, err := mid.get(key)
 	if err != nil {
-		panic(fmt.Errorf("[MIDADOL] ERROR: http.Get error: %s", err))
+		panic(err)
 	}
-	if resp.StatusCode == 404 {
-		panic("[MIDADOL] ERROR: value for key not found, received 404")
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	return val
+}
+
+// Same as [Get] but returns fallback instead of panicking if the value can't be fetched.
+func (mid *Mid) GetDefault(key string, fallback string) string {
+	val, err := mid.get(key)
 	if err != nil {
-		panic(fmt.Errorf("[MIDADOL] ERROR: io.ReadAll error: %s", err))
+		return fallback
 	}
-	return string(body)
+	return val
 }
 
 // Uses [Get] internally and tries to convert it to an int via [strconv.Atoi].
@@ -85,3 +128,163 @@ func (mid *Mid) GetBool(key string) bool {
 	}
 	return false
 }
+
+// Uses [Get] internally and tries to convert it to a [time/Duration] via [time.ParseDuration].
+// It panics if the conversion creates an error.
+func (mid *Mid) GetDuration(key string) time.Duration {
+	d, err := time.ParseDuration(mid.Get(key))
+	if err != nil {
+		panic(fmt.Errorf("[MIDADOL] ERROR: value of key is not a duration: %s", err))
+	}
+	return d
+}
+
+// Uses [Get] internally and tries to convert it to a float64 via [strconv.ParseFloat].
+// It panics if the conversion creates an error.
+func (mid *Mid) GetFloat64(key string) float64 {
+	f, err := strconv.ParseFloat(mid.Get(key), 64)
+	if err != nil {
+		panic(fmt.Errorf("[MIDADOL] ERROR: value of key is not a float64: %s", err))
+	}
+	return f
+}
+
+// Uses [Get] internally and unmarshals the value as JSON into out via [encoding/json.Unmarshal].
+// It panics if the value is not valid JSON for out's type.
+func (mid *Mid) GetJSON(key string, out any) {
+	if err := json.Unmarshal([]byte(mid.Get(key)), out); err != nil {
+		panic(fmt.Errorf("[MIDADOL] ERROR: value of key is not valid JSON: %s", err))
+	}
+}
+
+// Generic, non-panicking variant of the typed Get* methods: fetches key, parses it with parse and
+// returns the result, or fallback if the fetch or the parse fails. Useful for optional config that
+// doesn't have to be loaded at startup.
+func GetOr[T any](mid *Mid, key string, fallback T, parse func(string) (T, error)) T {
+	raw, err := mid.get(key)
+	if err != nil {
+		return fallback
+	}
+	val, err := parse(raw)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// Watches key for changes, calling onChange whenever the value differs from the last observed one.
+// It re-fetches on an interval of [Options.TTL] (or 5 seconds if TTL is unset), bypassing the cache
+// so it always observes the live value. Call the returned stop func to end the watch.
+func (mid *Mid) Watch(key string, onChange func(string)) (stop func()) {
+	interval := mid.opts.TTL
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	stopCh := make(chan struct{})
+	go func() {
+		last, _ := mid.fetch(key)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				val, err := mid.fetch(key)
+				if err != nil || val == last {
+					continue
+				}
+				last = val
+				onChange(val)
+			}
+		}
+	}()
+	return func() { close(stopCh) }
+}
+
+// Returns key's value from the cache if present and not expired, else fetches it (retrying on
+// transient errors) and populates the cache.
+func (mid *Mid) get(key string) (string, error) {
+	if val, ok := mid.cacheGet(key); ok {
+		return val, nil
+	}
+	val, err := mid.fetch(key)
+	if err != nil {
+		return "", err
+	}
+	mid.cacheSet(key, val)
+	return val, nil
+}
+
+// Fetches key from the Midadol server, retrying with exponential backoff on network errors and 5xx
+// responses up to [Options.Retries] times. 404 is returned immediately without retrying.
+func (mid *Mid) fetch(key string) (string, error) {
+	reqURL, err := url.JoinPath(mid.URL, "get", mid.App, key)
+	if err != nil {
+		return "", fmt.Errorf("[MIDADOL] ERROR: could not join URL: %w", err)
+	}
+
+	backoff := mid.opts.Backoff
+	var lastErr error
+	for attempt := 0; attempt <= mid.opts.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		resp, err := mid.httpClient().Get(reqURL)
+		if err != nil {
+			lastErr = fmt.Errorf("[MIDADOL] ERROR: http.Get error: %w", err)
+			continue
+		}
+
+		if resp.StatusCode == 404 {
+			resp.Body.Close()
+			return "", errors.New("[MIDADOL] ERROR: value for key not found, received 404")
+		}
+		if resp.StatusCode >= 500 {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("[MIDADOL] ERROR: server error, status %d", resp.StatusCode)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("[MIDADOL] ERROR: io.ReadAll error: %w", err)
+		}
+		return string(body), nil
+	}
+	return "", lastErr
+}
+
+// Returns the configured *http.Client, or [http.DefaultClient] (no timeout, matching the old
+// http.Get-based behavior) if mid was built as a bare struct literal instead of via a New* constructor.
+func (mid *Mid) httpClient() *http.Client {
+	if mid.client != nil {
+		return mid.client
+	}
+	return http.DefaultClient
+}
+
+func (mid *Mid) cacheGet(key string) (string, bool) {
+	if mid.opts.TTL <= 0 || mid.cache == nil {
+		return "", false
+	}
+	mid.cache.mu.Lock()
+	defer mid.cache.mu.Unlock()
+	entry, ok := mid.cache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+func (mid *Mid) cacheSet(key string, value string) {
+	if mid.opts.TTL <= 0 || mid.cache == nil {
+		return
+	}
+	mid.cache.mu.Lock()
+	defer mid.cache.mu.Unlock()
+	mid.cache.entries[key] = midCacheEntry{value: value, expiresAt: time.Now().Add(mid.opts.TTL)}
+}
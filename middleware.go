@@ -0,0 +1,179 @@
+package jote
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// A Middleware wraps an [net/http.Handler] to add behaviour before and/or after the wrapped handler runs.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes a set of [Middleware] into a single http.Handler.
+// Middlewares are applied in registration order, outermost first: the first middleware passed to
+// [NewChain] (or [Chain.Use]) is the first to see the request and the last to see the response.
+type Chain struct {
+	mws []Middleware
+}
+
+// Creates a new [Chain] from the given middlewares, applied outermost first.
+func NewChain(mws ...Middleware) *Chain {
+	return &Chain{mws: append([]Middleware{}, mws...)}
+}
+
+// Appends mw to the end of the chain (innermost so far) and returns the chain for easy call chaining.
+func (c *Chain) Use(mw Middleware) *Chain {
+	c.mws = append(c.mws, mw)
+	return c
+}
+
+// Wraps h with every middleware in the chain and returns the resulting handler.
+func (c *Chain) Then(h http.Handler) http.Handler {
+	final := h
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		final = c.mws[i](final)
+	}
+	return final
+}
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	skipPathContextKey
+)
+
+// Returns the request ID stored by [RequestID] in ctx, or "" if none is present.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// Generates a random per-request ID, honoring an incoming X-Request-ID header if present.
+// The ID is stored in the request's context (retrievable via [RequestIDFromContext]) and echoed
+// back via the X-Request-ID response header.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get("X-Request-ID")
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set("X-Request-ID", id)
+			ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Generates a random 16-byte hex-encoded ID for use as a request ID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fallbackRequestID()
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Used only if [crypto/rand] fails, which should never happen in practice.
+func fallbackRequestID() string {
+	return hex.EncodeToString([]byte(time.Now().String()))
+}
+
+// Marks the given paths to be skipped by instrumentation-aware middlewares ([AccessLog], [MetricsCounter])
+// further down the chain. Must be registered before them to take effect, e.g.
+// NewChain(SkipPath("/metrics"), AccessLog(logger), MetricsCounter(counter)).
+func SkipPath(paths ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, p := range paths {
+				if r.URL.Path == p {
+					r = r.WithContext(context.WithValue(r.Context(), skipPathContextKey, true))
+					break
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isSkipped(r *http.Request) bool {
+	skip, _ := r.Context().Value(skipPathContextKey).(bool)
+	return skip
+}
+
+// Logs every request to logger with ip, url, status, duration and the request ID from context (if any).
+// Wraps the response writer in a [loggingResponseWriter] so the status code is available to log, which
+// is also what [Recover] relies on to avoid double-writing the status after a panic.
+// The client IP is taken from [HttpRequestGetIP]; use [AccessLogWithExtractor] behind a reverse proxy.
+func AccessLog(logger *slog.Logger) Middleware {
+	return accessLog(logger, nil)
+}
+
+// Same as [AccessLog] but takes the client IP from a [ClientIPExtractor], so the header(s) to trust
+// are only honored when the request comes from a configured trusted proxy.
+func AccessLogWithExtractor(logger *slog.Logger, extractor *ClientIPExtractor) Middleware {
+	return accessLog(logger, extractor)
+}
+
+func accessLog(logger *slog.Logger, extractor *ClientIPExtractor) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			var srcIP string
+			if extractor != nil {
+				srcIP = extractor.Extract(r)
+			} else {
+				srcIP = HttpRequestGetIP(r)
+			}
+			lrw := &loggingResponseWriter{ResponseWriter: w, rc: 200}
+			next.ServeHTTP(lrw, r)
+			if isSkipped(r) {
+				return
+			}
+			logger.Info("webreq", "ip", srcIP, "url", r.URL, "duration", time.Since(start), "status", lrw.rc, "reqid", RequestIDFromContext(r.Context()))
+		})
+	}
+}
+
+// Recovers panics from next, dumping the stack at debug level, and writes a 500 if the response
+// hasn't been written to yet. Should be registered innermost so it wraps the real handler directly.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				re := recover()
+				if re == nil {
+					return
+				}
+				logger.Debug("panic recovered", "err", re, "stack", string(debug.Stack()))
+				if lrw, ok := w.(*loggingResponseWriter); ok {
+					if !lrw.written {
+						lrw.WriteHeader(http.StatusInternalServerError)
+					}
+				} else {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Increases counter by 1 for every request, skipping paths marked by [SkipPath].
+// This should be used together with [AddMetrics] to have a request counter metric.
+func MetricsCounter(counter *atomic.Uint64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			if !isSkipped(r) {
+				counter.Add(1)
+			}
+		})
+	}
+}
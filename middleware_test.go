@@ -0,0 +1,130 @@
+package jote
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func testLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, nil))
+}
+
+func TestRecoverWritesInternalServerErrorOnUnhandledPanic(t *testing.T) {
+	logger := testLogger(&bytes.Buffer{})
+	h := NewChain(AccessLog(logger), Recover(logger)).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRecoverDoesNotOverwriteAnAlreadyWrittenStatus(t *testing.T) {
+	logger := testLogger(&bytes.Buffer{})
+	h := NewChain(AccessLog(logger), Recover(logger)).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		panic("boom, but after the response was already started")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d (Recover must not overwrite a status already written)", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestRequestIDGeneratesAndEchoesHeader(t *testing.T) {
+	var gotID string
+	h := NewChain(RequestID()).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestIDFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if gotID == "" {
+		t.Fatal("expected a generated request ID in the request context")
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != gotID {
+		t.Errorf("X-Request-ID header = %q, want %q", got, gotID)
+	}
+}
+
+func TestRequestIDHonorsIncomingHeader(t *testing.T) {
+	var gotID string
+	h := NewChain(RequestID()).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestIDFromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-ID", "caller-supplied-id")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	if gotID != "caller-supplied-id" {
+		t.Errorf("context request ID = %q, want %q", gotID, "caller-supplied-id")
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "caller-supplied-id" {
+		t.Errorf("X-Request-ID header = %q, want %q", got, "caller-supplied-id")
+	}
+}
+
+func TestChainAppliesMiddlewareOutermostFirst(t *testing.T) {
+	var order []string
+	track := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+":before")
+				next.ServeHTTP(w, r)
+				order = append(order, name+":after")
+			})
+		}
+	}
+
+	h := NewChain(track("outer"), track("inner")).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	want := []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("order[%d] = %q, want %q (full: %v)", i, order[i], want[i], order)
+		}
+	}
+}
+
+func TestSkipPathSuppressesAccessLogAndMetricsCounter(t *testing.T) {
+	var buf bytes.Buffer
+	var counter atomic.Uint64
+	h := NewChain(SkipPath("/metrics"), AccessLog(testLogger(&buf)), MetricsCounter(&counter)).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no access log entry for a skipped path, got: %s", buf.String())
+	}
+	if counter.Load() != 0 {
+		t.Errorf("expected the counter to stay at 0 for a skipped path, got %d", counter.Load())
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+	if buf.Len() == 0 {
+		t.Error("expected an access log entry for a non-skipped path")
+	}
+	if counter.Load() != 1 {
+		t.Errorf("expected the counter to be 1 for a non-skipped path, got %d", counter.Load())
+	}
+}
@@ -2,30 +2,158 @@ package jote
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/pprof"
 	"os"
-	"runtime/pprof"
+	"runtime"
+	"runtime/trace"
+	"strconv"
+	"strings"
 	"time"
+
+	pprofruntime "runtime/pprof"
 )
 
 // Starts profiling using the pprof package if the PPROF environment variable is set.
-// The target file is the value of the pprof envvar.
+// The target file is the value of the pprof envvar. duration is the number of seconds to profile for,
+// overridable via the PPROF_DURATION envvar.
+// PPROF_MODE selects what to profile: "cpu" (default), "heap", "block", "mutex" or "trace".
 func ProfilingUntilTimeIfSet(duration int) {
 	pprofLocation := os.Getenv("PPROF")
 	if pprofLocation == "" {
 		return
 	}
-	fmt.Println("DEBUG/jote starting profiling")
-	f, err := os.Create(pprofLocation)
+	if d, err := strconv.Atoi(os.Getenv("PPROF_DURATION")); err == nil {
+		duration = d
+	}
+	mode := os.Getenv("PPROF_MODE")
+	if mode == "" {
+		mode = "cpu"
+	}
+	switch mode {
+	case "cpu", "heap", "block", "mutex", "trace":
+	default:
+		fmt.Println("DEBUG/jote unknown PPROF_MODE", mode, "falling back to cpu")
+		mode = "cpu"
+	}
+
+	fmt.Println("DEBUG/jote starting profiling:", mode)
+	switch mode {
+	case "cpu":
+		stop := StartCPUProfile(pprofLocation)
+		go func() {
+			time.Sleep(time.Duration(duration) * time.Second)
+			fmt.Println("DEBUG/jote stopping profiling")
+			if err := stop(); err != nil {
+				fmt.Println("DEBUG/jote error stopping profiling:", err)
+			}
+		}()
+	case "heap":
+		go func() {
+			time.Sleep(time.Duration(duration) * time.Second)
+			fmt.Println("DEBUG/jote writing heap profile")
+			if err := WriteHeapProfile(pprofLocation); err != nil {
+				fmt.Println("DEBUG/jote error writing heap profile:", err)
+			}
+		}()
+	case "block":
+		runtime.SetBlockProfileRate(1)
+		go func() {
+			time.Sleep(time.Duration(duration) * time.Second)
+			fmt.Println("DEBUG/jote writing block profile")
+			writeNamedProfile("block", pprofLocation)
+			runtime.SetBlockProfileRate(0)
+		}()
+	case "mutex":
+		runtime.SetMutexProfileFraction(1)
+		go func() {
+			time.Sleep(time.Duration(duration) * time.Second)
+			fmt.Println("DEBUG/jote writing mutex profile")
+			writeNamedProfile("mutex", pprofLocation)
+			runtime.SetMutexProfileFraction(0)
+		}()
+	case "trace":
+		f, err := os.Create(pprofLocation)
+		if err != nil {
+			panic(err)
+		}
+		if err := trace.Start(f); err != nil {
+			panic(err)
+		}
+		go func() {
+			time.Sleep(time.Duration(duration) * time.Second)
+			fmt.Println("DEBUG/jote stopping trace")
+			trace.Stop()
+			f.Close()
+		}()
+	}
+}
+
+// Starts a CPU profile written to path, returning a func to stop it and close the file.
+func StartCPUProfile(path string) (stop func() error) {
+	f, err := os.Create(path)
 	if err != nil {
 		panic(err)
 	}
-	// runtime.SetCPUProfileRate(1000000)
-	if err := pprof.StartCPUProfile(f); err != nil {
+	if err := pprofruntime.StartCPUProfile(f); err != nil {
 		panic(err)
 	}
-	go func() {
-		time.Sleep(time.Duration(duration) * time.Second)
-		fmt.Println("DEBUG/jote stopping profiling")
-		pprof.StopCPUProfile()
-	}()
+	return func() error {
+		pprofruntime.StopCPUProfile()
+		return f.Close()
+	}
+}
+
+// Writes a heap snapshot to path, forcing a GC first so the profile reflects live objects.
+func WriteHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	return pprofruntime.WriteHeapProfile(f)
+}
+
+func writeNamedProfile(name string, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println("DEBUG/jote error creating profile file:", err)
+		return
+	}
+	defer f.Close()
+	if err := pprofruntime.Lookup(name).WriteTo(f, 0); err != nil {
+		fmt.Println("DEBUG/jote error writing profile:", err)
+	}
+}
+
+// Mounts the standard [net/http/pprof] handlers (index, cmdline, profile, symbol, trace, and every
+// named profile registered with [runtime/pprof] — heap, goroutine, threadcreate, block, mutex,
+// allocs) under prefix, gated by auth: if auth is non-nil and returns false for a request, that
+// request gets a 403 instead of the profile. This lets pprof be safely exposed on a mux that's
+// otherwise reachable, e.g. an internal admin mux, rather than only at startup.
+// Each named profile is registered at its own exact path rather than relying on [pprof.Index]'s
+// internal routing, which only recognizes the hardcoded "/debug/pprof/" prefix.
+func RegisterPprof(mux *http.ServeMux, prefix string, auth func(*http.Request) bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	wrap := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if auth != nil && !auth(r) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			h(w, r)
+		}
+	}
+
+	mux.HandleFunc(prefix+"/", wrap(pprof.Index))
+	mux.HandleFunc(prefix+"/cmdline", wrap(pprof.Cmdline))
+	mux.HandleFunc(prefix+"/profile", wrap(pprof.Profile))
+	mux.HandleFunc(prefix+"/symbol", wrap(pprof.Symbol))
+	mux.HandleFunc(prefix+"/trace", wrap(pprof.Trace))
+	for _, p := range pprofruntime.Profiles() {
+		name := p.Name()
+		mux.HandleFunc(prefix+"/"+name, wrap(pprof.Handler(name).ServeHTTP))
+	}
 }
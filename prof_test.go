@@ -0,0 +1,43 @@
+package jote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterPprofAuthBlocksUnauthorized(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterPprof(mux, "/debug/pprof", func(r *http.Request) bool { return false })
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/", nil))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRegisterPprofAuthAllowsAuthorized(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterPprof(mux, "/debug/pprof", func(r *http.Request) bool { return true })
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRegisterPprofWithNilAuthAllowsEverything(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterPprof(mux, "/debug/pprof", nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/goroutine", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
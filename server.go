@@ -0,0 +1,158 @@
+package jote
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// A configurable HTTP server with graceful shutdown, optional TLS and shutdown hooks.
+// Use [RunMux] for the common case of plain HTTP with sane defaults.
+type Server struct {
+	Addr    string
+	Handler http.Handler
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// How long [Server.Run] waits for in-flight requests to finish once shutdown starts.
+	// Defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+
+	// If set, the server serves TLS. TLSConfig alone is enough if it already has certificates
+	// configured (e.g. via GetCertificate); otherwise set CertFile/KeyFile.
+	TLSConfig *tls.Config
+	CertFile  string
+	KeyFile   string
+
+	// Run at shutdown in reverse registration order, e.g. to close DB pools or flush queues.
+	// Each hook gets the shutdown context, so it should respect its deadline.
+	OnShutdown []func(context.Context) error
+
+	// Used to log "listening"/"shutting down" messages, same as [RunMux]. May be left nil.
+	Logger *slog.Logger
+}
+
+// Starts the server and blocks until it shuts down, either because ctx is cancelled, a SIGINT/SIGTERM
+// is received, or the listener fails. On shutdown it stops accepting new connections, waits up to
+// ShutdownTimeout for in-flight requests to finish, then runs OnShutdown hooks in reverse order.
+func (s *Server) Run(ctx context.Context) error {
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return errors.New("jote: Server.CertFile and Server.KeyFile must both be set or both be empty")
+	}
+
+	shutdownTimeout := s.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 10 * time.Second
+	}
+
+	tracker := NewIdleTracker()
+	srv := &http.Server{
+		Addr:           s.Addr,
+		Handler:        s.Handler,
+		ReadTimeout:    s.ReadTimeout,
+		WriteTimeout:   s.WriteTimeout,
+		IdleTimeout:    s.IdleTimeout,
+		TLSConfig:      s.TLSConfig,
+		MaxHeaderBytes: 1 << 20,
+		ConnState:      tracker.ConnState,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	serveErrCh := make(chan error, 1)
+	go func() {
+		s.logInfo("Now listening", "addr", s.Addr)
+		var err error
+		switch {
+		case s.CertFile != "" && s.KeyFile != "":
+			err = srv.ListenAndServeTLS(s.CertFile, s.KeyFile)
+		case s.TLSConfig != nil:
+			err = srv.ListenAndServeTLS("", "")
+		default:
+			err = srv.ListenAndServe()
+		}
+		serveErrCh <- err
+	}()
+
+	select {
+	case <-sigCh:
+		s.logInfo("Signal received, shutting down...")
+	case <-ctx.Done():
+		s.logInfo("Context cancelled, shutting down...")
+	case err := <-serveErrCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	s.logInfo("Waiting for connections to close", "open", tracker.Open())
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		s.logError("Error at httpServer.Shutdown", "err", shutdownErr)
+	}
+
+	for i := len(s.OnShutdown) - 1; i >= 0; i-- {
+		if err := s.OnShutdown[i](shutdownCtx); err != nil {
+			s.logError("Error running OnShutdown hook", "err", err)
+		}
+	}
+
+	<-serveErrCh
+	return shutdownErr
+}
+
+func (s *Server) logInfo(msg string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Info(msg, args...)
+	}
+}
+
+func (s *Server) logError(msg string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Error(msg, args...)
+	}
+}
+
+// Tracks the number of open connections via [net/http.Server.ConnState], so [Server.Run] can report
+// how many connections it's waiting on at shutdown instead of blindly waiting out ShutdownTimeout.
+type IdleTracker struct {
+	open atomic.Int64
+}
+
+// Creates an [IdleTracker]. Its ConnState method should be assigned to [net/http.Server.ConnState].
+func NewIdleTracker() *IdleTracker {
+	return &IdleTracker{}
+}
+
+// Open returns the number of connections currently open (active or idle, not yet closed).
+func (t *IdleTracker) Open() int64 {
+	return t.open.Load()
+}
+
+// ConnState is an [net/http.Server.ConnState] callback that maintains the open connection count.
+func (t *IdleTracker) ConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		t.open.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		t.open.Add(-1)
+	}
+}
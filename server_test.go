@@ -0,0 +1,72 @@
+package jote
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServerRunStopsOnContextCancellation(t *testing.T) {
+	srv := &Server{
+		Addr:            "127.0.0.1:0",
+		Handler:         http.NewServeMux(),
+		ShutdownTimeout: time.Second,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+}
+
+func TestServerRunRejectsMismatchedCertAndKey(t *testing.T) {
+	srv := &Server{
+		Addr:     "127.0.0.1:0",
+		Handler:  http.NewServeMux(),
+		CertFile: "cert.pem",
+	}
+	if err := srv.Run(context.Background()); err == nil {
+		t.Fatal("expected an error when only CertFile is set, got nil")
+	}
+}
+
+func TestServerRunRunsOnShutdownHooksInReverseOrder(t *testing.T) {
+	var order []string
+	srv := &Server{
+		Addr:            "127.0.0.1:0",
+		Handler:         http.NewServeMux(),
+		ShutdownTimeout: time.Second,
+		OnShutdown: []func(context.Context) error{
+			func(context.Context) error { order = append(order, "first"); return nil },
+			func(context.Context) error { order = append(order, "second"); return nil },
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+
+	want := []string{"second", "first"}
+	if len(order) != len(want) || order[0] != want[0] || order[1] != want[1] {
+		t.Errorf("OnShutdown call order = %v, want %v", order, want)
+	}
+}
@@ -52,32 +52,18 @@ func ReadConfigYAML(path string, config any) {
 
 // A wrapper for [log/slog] that creates a slog logger.
 // If path is "stdout" it will create a stdout logger, else it will log into the path file.
+// Deprecated: this is now a thin shim over [NewLogger] with rotation disabled. Use [NewLogger]
+// directly if you want rotation, JSON output or to close the underlying file.
 func CreateLogger(path string) *slog.Logger {
-	if path == "stdout" {
-		return slog.New(slog.NewTextHandler(os.Stdout, nil))
-	} else {
-		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			panic(err)
-		}
-		return slog.New(slog.NewTextHandler(f, nil))
-	}
+	logger, _, _ := NewLogger(LoggerConfig{Path: path, Format: "text"})
+	return logger
 }
 
 // Same as CreateLogger but returns the loglevel to control the logger.
+// Deprecated: this is now a thin shim over [NewLogger] with rotation disabled.
 func CreateLoggerWithLevel(path string) (*slog.Logger, *slog.LevelVar) {
-	loglvl := new(slog.LevelVar)
-	logHO := &slog.HandlerOptions{Level: loglvl}
-
-	if path == "stdout" {
-		return slog.New(slog.NewTextHandler(os.Stdout, logHO)), loglvl
-	} else {
-		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			panic(err)
-		}
-		return slog.New(slog.NewTextHandler(f, logHO)), loglvl
-	}
+	logger, loglvl, _ := NewLogger(LoggerConfig{Path: path, Format: "text"})
+	return logger, loglvl
 }
 
 // Small wrapper to start a goroutine and defer recover.
@@ -7,11 +7,8 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"strconv"
 	"sync/atomic"
-	"syscall"
 	"time"
 )
 
@@ -33,6 +30,8 @@ func AddMetrics(mux *http.ServeMux, name string, counter *atomic.Uint64) {
 }
 
 // Returns a request's IP, in order of priority: X-Real-IP header, X-Forwarded-For header, r.RemoteAddr, "".
+// This trusts these headers unconditionally, which is wrong behind a reverse proxy that isn't the
+// immediate peer (XFF is attacker-controllable). Use [ClientIPExtractor] if that matters to you.
 func HttpRequestGetIP(r *http.Request) string {
 	if sip := r.Header.Get("X-Real-IP"); sip != "" {
 		return sip
@@ -47,124 +46,62 @@ func HttpRequestGetIP(r *http.Request) string {
 // This is a [net/http.ResponseWriter] compatible http.Responsewriter with an extra "rc" (ReturnCode) variable.
 type loggingResponseWriter struct {
 	http.ResponseWriter
-	rc int
+	rc      int
+	written bool
 }
 
 // Overwrite WriteHeader to save the statusCode to a variable that can be read later.
 func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 	r.rc = statusCode
+	r.written = true
+}
+
+// Overwrite Write to record that the response has been started (with an implicit 200 if WriteHeader wasn't called).
+func (r *loggingResponseWriter) Write(b []byte) (int, error) {
+	r.written = true
+	return r.ResponseWriter.Write(b)
 }
 
 // This wraps the mux (next) to log every request to logger. Recovers panics and ignores the /metrics path.
 // It logs the ip, url, duration, status and error (recovered from panic).
-// The special statuscode logging via the [loggingResponseWriter] type adds a ~50-100ns overhead to every request.
+// Deprecated: this is now a thin shim over [Chain] built from [SkipPath], [AccessLog] and [Recover].
+// The access log entry no longer carries the recovered error; [Recover] logs it at debug level instead.
+// Prefer building your own chain with [NewChain] if you need a different combination of middlewares.
 func AddLoggingToMux(next http.Handler, logger *slog.Logger) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		srcIP := HttpRequestGetIP(r)
-		lrw := loggingResponseWriter{
-			ResponseWriter: w,
-			rc:             200,
-		}
-		defer func() {
-			re := recover()
-			if re != nil {
-				lrw.WriteHeader(500)
-			}
-			if r.URL.Path == "/metrics" {
-				return
-			}
-			logger.Info("webreq", "ip", srcIP, "url", r.URL, "duration", time.Since(start), "status", lrw.rc, "err", re)
-		}()
-		next.ServeHTTP(&lrw, r)
-	})
+	return NewChain(SkipPath("/metrics"), AccessLog(logger), Recover(logger)).Then(next)
 }
 
 // Same as [AddLoggingToMux] but this function does not log the return code.
+// Deprecated: the [AccessLog] middleware always tracks the return code, so this is now identical to [AddLoggingToMux].
+// It is kept only so existing callers don't have to change their signature.
 func AddLoggingToMuxNoRC(next http.Handler, logger *slog.Logger) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		srcIP := HttpRequestGetIP(r)
-		defer func() {
-			re := recover()
-			if re != nil {
-				w.WriteHeader(500)
-			}
-			if r.URL.Path == "/metrics" {
-				return
-			}
-			logger.Info("webreq", "ip", srcIP, "url", r.URL, "duration", time.Since(start), "err", re)
-		}()
-		next.ServeHTTP(w, r)
-	})
+	return NewChain(SkipPath("/metrics"), AccessLog(logger), Recover(logger)).Then(next)
 }
 
 // Same as [AddLoggingToMux] but increases the counter by 1 every request.
 // This should be used together with [AddMetrics] to have a request counter metric.
+// Deprecated: this is now a thin shim over [Chain]; prefer [MetricsCounter] directly in your own chain.
 func AddLoggingToMuxWithCounter(next http.Handler, logger *slog.Logger, counter *atomic.Uint64) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		srcIP := r.RemoteAddr
-		if sip := r.Header.Get("X-Real-IP"); sip != "" {
-			srcIP = sip
-		} else {
-			pIP := net.ParseIP(srcIP)
-			if pIP != nil {
-				srcIP = pIP.String()
-			}
-		}
-		lrw := loggingResponseWriter{
-			ResponseWriter: w,
-			rc:             200,
-		}
-		defer func() {
-			re := recover()
-			if re != nil {
-				lrw.WriteHeader(500)
-			}
-			if r.URL.Path == "/metrics" {
-				return
-			}
-			counter.Add(1)
-			logger.Info("webreq", "ip", srcIP, "url", r.URL, "duration", time.Since(start), "status", lrw.rc, "err", re)
-		}()
-		next.ServeHTTP(&lrw, r)
-	})
+	return NewChain(SkipPath("/metrics"), AccessLog(logger), MetricsCounter(counter), Recover(logger)).Then(next)
 }
 
 // Creates a [net/http.Server] that uses the provided mux to run the webserver and shutdown gracefully if Interrupt,SIGINT or SIGTERM signals are received..
-// Timeouts for read/write/idle are 10 seconds. The shutdown does not have a context deadline, so it should use the IdleTimeout.
+// Timeouts for read/write/idle are 10 seconds, and shutdown gets 10 seconds to finish in-flight requests.
+// Thin wrapper around [Server] for the common case; use [Server] directly for TLS or shutdown hooks.
 func RunMux(addr string, mux http.Handler, logger *slog.Logger) {
-	logger.Info("Now listening", "addr", addr)
-	srv := &http.Server{
-		Addr:           addr,
-		Handler:        mux,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		IdleTimeout:    10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+	srv := &Server{
+		Addr:            addr,
+		Handler:         mux,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		IdleTimeout:     10 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+		Logger:          logger,
 	}
-
-	idleConnsClosed := make(chan struct{})
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		signal.Notify(sigint, syscall.SIGINT)
-		signal.Notify(sigint, syscall.SIGTERM)
-		<-sigint
-		logger.Info("Signal received, shutting down...")
-		if err := srv.Shutdown(context.Background()); err != nil {
-			logger.Error("Error at httpServer.Shutdown", "err", err)
-		}
-		close(idleConnsClosed)
-	}()
-
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		logger.Error("Error at ListenAndServe", "err", err)
+	if err := srv.Run(context.Background()); err != nil {
+		logger.Error("Error running server", "err", err)
 	}
-
-	<-idleConnsClosed
 }
 
 // Same as [RunMux] but without the graceful shutdown.